@@ -15,13 +15,28 @@ func main() {
 		log.Fatalf("failed to parse config: %v", err)
 	}
 
-	collector := app.NewStatsCollector(config)
+	collector, err := app.NewStatsCollector(config)
+	if err != nil {
+		log.Fatalf("failed to create stats collector: %v", err)
+	}
+	defer func() {
+		if err = collector.Close(); err != nil {
+			log.Printf("failed to close events output: %v", err)
+		}
+	}()
 
-	processWithLoading(func() {
+	collect := func() {
 		if err = collector.CollectStats(); err != nil {
 			log.Fatalf("\nfailed to collect statistics: %v", err)
 		}
-	}, "Collecting statistics")
+	}
+
+	// the spinner and the ndjson-events stream both write progress to the user, so only one runs at a time
+	if config.Format == "ndjson-events" {
+		collect()
+	} else {
+		processWithLoading(collect, "Collecting statistics")
+	}
 
 	if err = collector.PrintStats(); err != nil {
 		log.Fatalf("\nfailed to print statistics: %v", err)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/spf13/pflag"
 	"os"
+	"regexp"
 	"strings"
 )
 
@@ -20,23 +21,51 @@ var (
 	}
 
 	ValidFormats = map[string]struct{}{
-		"tabular":    {},
-		"csv":        {},
-		"json":       {},
-		"json-lines": {},
+		"tabular":       {},
+		"csv":           {},
+		"json":          {},
+		"json-lines":    {},
+		"ndjson-events": {},
 	}
+
+	ValidBackends = map[string]struct{}{
+		"exec":   {},
+		"go-git": {},
+	}
+
+	ValidModes = map[string]struct{}{
+		"blame": {},
+		"diff":  {},
+	}
+
+	// dateFlagPattern is the only --since/--until format accepted, so both the exec and go-git backends
+	// parse it identically
+	dateFlagPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
 )
 
 type Config struct {
-	UseCommitter bool
-	RepoPath     string
-	Revision     string
-	OrderBy      string
-	Format       string
-	Extensions   []string
-	Languages    []string
-	Excludes     []string
-	RestrictTo   []string
+	UseCommitter         bool
+	RepoPath             string
+	Revision             string
+	OrderBy              string
+	Format               string
+	Backend              string
+	IncludeVendored      bool
+	IncludeGenerated     bool
+	IncludeDocumentation bool
+	MailmapPath          string
+	IgnoreRevsFile       string
+	IgnoreRevs           []string
+	Mode                 string
+	Since                string
+	Until                string
+	FromRev              string
+	ToRev                string
+	EventsOut            string
+	Extensions           []string
+	Languages            []string
+	Excludes             []string
+	RestrictTo           []string
 }
 
 type LanguageExtension struct {
@@ -52,7 +81,20 @@ func ParseConfig() (Config, error) {
 	pflag.StringVar(&config.Revision, "revision", "HEAD", "Git revision to analyze")
 	pflag.StringVar(&config.OrderBy, "order-by", "lines", "Key to sort results by: lines, commits, files")
 	pflag.BoolVar(&config.UseCommitter, "use-committer", false, "Use committer instead of author for calculations")
-	pflag.StringVar(&config.Format, "format", "tabular", "Output format: tabular, csv, json, json-lines")
+	pflag.StringVar(&config.Format, "format", "tabular", "Output format: tabular, csv, json, json-lines, ndjson-events")
+	pflag.StringVar(&config.EventsOut, "events-out", "", "Path to write ndjson-events output to (defaults to stdout)")
+	pflag.StringVar(&config.Backend, "backend", "exec", "Git backend to use: exec, go-git")
+	pflag.BoolVar(&config.IncludeVendored, "include-vendored", false, "Include files marked linguist-vendored in .gitattributes")
+	pflag.BoolVar(&config.IncludeGenerated, "include-generated", false, "Include files marked linguist-generated in .gitattributes")
+	pflag.BoolVar(&config.IncludeDocumentation, "include-documentation", false, "Include files marked linguist-documentation in .gitattributes")
+	pflag.StringVar(&config.MailmapPath, "mailmap", "", "Path to a .mailmap file (defaults to .mailmap at the repository root)")
+	pflag.StringVar(&config.IgnoreRevsFile, "ignore-revs-file", "", "Path to a file of commit SHAs to look through when blaming")
+	pflag.StringArrayVar(&config.IgnoreRevs, "ignore-rev", nil, "Commit SHA to look through when blaming (repeatable)")
+	pflag.StringVar(&config.Mode, "mode", "blame", "Attribution mode: blame (snapshot at revision), diff (commit range)")
+	pflag.StringVar(&config.Since, "since", "", "Only consider commits made after this date, format YYYY-MM-DD (diff mode)")
+	pflag.StringVar(&config.Until, "until", "", "Only consider commits made before this date, format YYYY-MM-DD (diff mode)")
+	pflag.StringVar(&config.FromRev, "from-rev", "", "Exclusive start of the commit range (diff mode)")
+	pflag.StringVar(&config.ToRev, "to-rev", "", "Inclusive end of the commit range, defaults to --revision (diff mode)")
 	pflag.StringSliceVar(&config.Extensions, "extensions", nil, "Comma-separated list of file extensions to include")
 	pflag.StringSliceVar(&config.Languages, "languages", nil, "Comma-separated list of languages to include")
 	pflag.StringSliceVar(&config.Excludes, "exclude", nil, "Comma-separated list of Glob patterns to exclude files")
@@ -67,6 +109,22 @@ func ParseConfig() (Config, error) {
 		return Config{}, fmt.Errorf("invalid order-by: %s", config.OrderBy)
 	}
 
+	if _, ok := ValidBackends[config.Backend]; !ok {
+		return Config{}, fmt.Errorf("invalid backend: %s", config.Backend)
+	}
+
+	if _, ok := ValidModes[config.Mode]; !ok {
+		return Config{}, fmt.Errorf("invalid mode: %s", config.Mode)
+	}
+
+	if config.Since != "" && !dateFlagPattern.MatchString(config.Since) {
+		return Config{}, fmt.Errorf("invalid --since %q: must be in YYYY-MM-DD format", config.Since)
+	}
+
+	if config.Until != "" && !dateFlagPattern.MatchString(config.Until) {
+		return Config{}, fmt.Errorf("invalid --until %q: must be in YYYY-MM-DD format", config.Until)
+	}
+
 	languagesMap := make(map[string]struct{})
 	for _, lang := range config.Languages {
 		languagesMap[strings.ToLower(lang)] = struct{}{}
@@ -0,0 +1,72 @@
+package app
+
+import "fmt"
+
+// BlameChunk is a contiguous group of lines attributed to a single commit, as reported by git blame
+type BlameChunk struct {
+	CommitHash string
+	Author     string
+	Lines      int
+}
+
+// IgnoreRevs is the set of commits that blame should look through, as configured by --ignore-revs-file and
+// --ignore-rev
+type IgnoreRevs struct {
+	File string
+	Revs []string
+}
+
+// DiffRange bounds a commit walk for diff-mode attribution: FromRev..ToRev (ToRev defaults to HEAD when
+// empty) restricted to commits made between Since and Until, if set
+type DiffRange struct {
+	FromRev string
+	ToRev   string
+	Since   string
+	Until   string
+}
+
+// CommitDiffStat is one commit's contribution within a DiffRange
+type CommitDiffStat struct {
+	CommitHash   string
+	Author       string
+	LinesAdded   int
+	LinesRemoved int
+	Files        []string
+}
+
+// GitBackend abstracts the git operations needed by StatsCollector, so that they can be served either by
+// shelling out to the git binary or by an in-process implementation such as go-git
+type GitBackend interface {
+	// ListFiles returns the tracked file paths at revision in the repository at repoPath
+	ListFiles(repoPath, revision string) ([]string, error)
+
+	// BlameFile returns the per-commit line-attribution chunks for file at revision. When useCommitter
+	// is true, chunks are attributed to the committer instead of the author. Commits in ignore are looked
+	// through: their lines are attributed to the nearest non-ignored ancestor instead, except lines the
+	// ignored commit added itself, which stay attributed to it
+	BlameFile(repoPath, revision, file string, useCommitter bool, ignore IgnoreRevs) ([]BlameChunk, error)
+
+	// FileCreator returns the commit hash and author that created file, for files with no blame output
+	// (e.g. empty files)
+	FileCreator(repoPath, revision, file string, useCommitter bool) (commitHash, author string, err error)
+
+	// ReadFile returns the contents of path as of revision. exists is false, with no error, when path is
+	// not present at that revision
+	ReadFile(repoPath, revision, path string) (content string, exists bool, err error)
+
+	// WalkDiffs walks the commits in rng and returns each commit's author, its total added and removed
+	// lines and the files it touched, for diff-mode attribution
+	WalkDiffs(repoPath string, rng DiffRange) ([]CommitDiffStat, error)
+}
+
+// NewGitBackend builds the GitBackend selected by the --backend flag
+func NewGitBackend(backend string) (GitBackend, error) {
+	switch backend {
+	case "exec":
+		return &ExecBackend{}, nil
+	case "go-git":
+		return &GoGitBackend{}, nil
+	default:
+		return nil, fmt.Errorf("unknown git backend: %s", backend)
+	}
+}
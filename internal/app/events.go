@@ -0,0 +1,51 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Event is a single NDJSON record emitted by StatsCollector while --format=ndjson-events is active
+type Event map[string]any
+
+// Emit marshals event as one line of JSON and writes it to the collector's configured events sink. It is
+// a no-op when ndjson-events output is not enabled. A write failure is recorded on the collector rather
+// than returned here, since Emit is also called from file-processing goroutines that don't otherwise
+// report errors; it surfaces later through eventsError
+func (sc *StatsCollector) Emit(event Event) {
+	if sc.eventsOut == nil {
+		return
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.eventsErr != nil {
+		return
+	}
+
+	if _, err := fmt.Fprintln(sc.eventsOut, string(data)); err != nil {
+		sc.eventsErr = fmt.Errorf("failed to write event: %w", err)
+	}
+}
+
+// eventsError returns the first error encountered while writing to the events sink, if any
+func (sc *StatsCollector) eventsError() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.eventsErr
+}
+
+// emitProgress emits a "progress" event reporting how many of the total files have been processed so far
+func (sc *StatsCollector) emitProgress() {
+	sc.mu.Lock()
+	sc.done++
+	done, total := sc.done, sc.total
+	sc.mu.Unlock()
+
+	sc.Emit(Event{"type": "progress", "done": done, "total": total})
+}
@@ -0,0 +1,230 @@
+package app
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	commitLen     = 40
+	commitLineLen = 46
+)
+
+// ExecBackend implements GitBackend by shelling out to the git binary found on PATH
+type ExecBackend struct{}
+
+// ListFiles lists the repository's tracked files via `git ls-tree`
+func (b *ExecBackend) ListFiles(repoPath, revision string) ([]string, error) {
+	cmd := exec.Command("git", "ls-tree", "-r", revision, "--name-only")
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in repository: %w", err)
+	}
+
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
+
+// BlameFile runs `git blame --porcelain` on file and groups its output into BlameChunks. ignore is passed
+// through as --ignore-revs-file / --ignore-rev so git itself looks through the ignored commits
+func (b *ExecBackend) BlameFile(repoPath, revision, file string, useCommitter bool, ignore IgnoreRevs) ([]BlameChunk, error) {
+	args := []string{"blame", "--porcelain"}
+	if ignore.File != "" {
+		args = append(args, "--ignore-revs-file", ignore.File)
+	}
+	for _, rev := range ignore.Revs {
+		args = append(args, "--ignore-rev", rev)
+	}
+	args = append(args, revision, "--", file)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git blame: %w", err)
+	}
+
+	commits := make(map[string]string)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+
+	var chunks []BlameChunk
+	for i, line := range lines {
+		if !isCommitLine(line) {
+			continue
+		}
+
+		commitInfo := strings.Split(line, " ")
+		commitHash := commitInfo[0]
+
+		author, exists := commits[commitHash]
+		if !exists {
+			author = getAuthor(lines[i:], useCommitter)
+			commits[commitHash] = author
+		}
+
+		lineCount, err := strconv.Atoi(commitInfo[3])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse commit line count %s: %w", line, err)
+		}
+
+		chunks = append(chunks, BlameChunk{CommitHash: commitHash, Author: author, Lines: lineCount})
+	}
+
+	return chunks, nil
+}
+
+// FileCreator finds the commit that introduced file via `git log`, for files with no blame output. git
+// log lists commits newest-first, so the true creator is the last commit/author pair in the output,
+// matching the go-git backend's FileCreator
+func (b *ExecBackend) FileCreator(repoPath, revision, file string, useCommitter bool) (string, string, error) {
+	format := "--pretty=format:%H%n%an <%ae>"
+	if useCommitter {
+		format = "--pretty=format:%H%n%cn <%ce>"
+	}
+
+	cmd := exec.Command("git", "log", format, revision, "--", file)
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to run git log: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) < 2 {
+		return "", "", fmt.Errorf("no commits found for file %s", file)
+	}
+
+	createCommit := lines[len(lines)-2]
+	fileAuthor := lines[len(lines)-1]
+
+	return createCommit, fileAuthor, nil
+}
+
+// WalkDiffs runs `git log --numstat` over rng and groups its output into CommitDiffStats
+func (b *ExecBackend) WalkDiffs(repoPath string, rng DiffRange) ([]CommitDiffStat, error) {
+	toRev := rng.ToRev
+	if toRev == "" {
+		toRev = "HEAD"
+	}
+	rangeExpr := toRev
+	if rng.FromRev != "" {
+		rangeExpr = rng.FromRev + ".." + toRev
+	}
+
+	args := []string{"log", "--numstat", "--pretty=format:@@commit %H%n@@author %an <%ae>"}
+	if rng.Since != "" {
+		args = append(args, "--since="+rng.Since)
+	}
+	if rng.Until != "" {
+		args = append(args, "--until="+rng.Until)
+	}
+	args = append(args, rangeExpr)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git log: %w", err)
+	}
+
+	var stats []CommitDiffStat
+	var current *CommitDiffStat
+	for _, line := range strings.Split(string(output), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@commit "):
+			if current != nil {
+				stats = append(stats, *current)
+			}
+			current = &CommitDiffStat{CommitHash: strings.TrimPrefix(line, "@@commit ")}
+		case strings.HasPrefix(line, "@@author "):
+			if current != nil {
+				current.Author = strings.TrimPrefix(line, "@@author ")
+			}
+		case line == "":
+			continue
+		default:
+			if current == nil {
+				continue
+			}
+
+			fields := strings.SplitN(line, "\t", 3)
+			if len(fields) != 3 {
+				continue
+			}
+
+			added, _ := strconv.Atoi(fields[0])
+			removed, _ := strconv.Atoi(fields[1])
+			current.LinesAdded += added
+			current.LinesRemoved += removed
+			current.Files = append(current.Files, fields[2])
+		}
+	}
+	if current != nil {
+		stats = append(stats, *current)
+	}
+
+	return stats, nil
+}
+
+// ReadFile reads path at revision via `git show`, treating any failure as the path not existing
+func (b *ExecBackend) ReadFile(repoPath, revision, path string) (string, bool, error) {
+	cmd := exec.Command("git", "show", revision+":"+path)
+	cmd.Dir = repoPath
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false, nil
+	}
+
+	return string(output), true, nil
+}
+
+// isCommitLine determines whether the output line of git blame command contains information about commit
+func isCommitLine(line string) bool {
+	if len(line) < commitLineLen || len(strings.Split(line, " ")) < 4 {
+		return false
+	}
+
+	for _, c := range line[:commitLen] {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getAuthor returns the author or committer of the commit in "Name <email>" form, depending on
+// useCommitter, matching the go-git backend's signatureName so both backends produce identical statsMap
+// keys
+//
+// - lines: []string - a list of lines that starts with the target commit line
+func getAuthor(lines []string, useCommitter bool) string {
+	prefix := "author"
+	if useCommitter {
+		prefix = "committer"
+	}
+
+	var name, email string
+	for _, line := range lines {
+		// the tab-prefixed content line ends the commit's header block
+		if strings.HasPrefix(line, "\t") {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, prefix+"-mail "):
+			email = strings.Trim(strings.TrimPrefix(line, prefix+"-mail "), "<>")
+		case strings.HasPrefix(line, prefix+" "):
+			name = strings.TrimPrefix(line, prefix+" ")
+		}
+	}
+
+	return fmt.Sprintf("%s <%s>", name, email)
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// attributePattern is a single pattern line parsed from a .gitattributes file
+type attributePattern struct {
+	pattern string
+	attrs   map[string]string
+}
+
+// GitAttributes evaluates .gitattributes files for a revision, following go-git's
+// plumbing/format/gitattributes model: patterns are read from every directory up a file's path and
+// evaluated in order, with the last match winning
+type GitAttributes struct {
+	backend  GitBackend
+	repoPath string
+	revision string
+	dirCache map[string][]attributePattern
+}
+
+// NewGitAttributes builds a GitAttributes that reads .gitattributes files through backend
+func NewGitAttributes(backend GitBackend, repoPath, revision string) *GitAttributes {
+	return &GitAttributes{
+		backend:  backend,
+		repoPath: repoPath,
+		revision: revision,
+		dirCache: make(map[string][]attributePattern),
+	}
+}
+
+// IsVendored reports whether file is marked linguist-vendored
+func (ga *GitAttributes) IsVendored(file string) bool {
+	return ga.attr(file, "linguist-vendored") == "true"
+}
+
+// IsGenerated reports whether file is marked linguist-generated
+func (ga *GitAttributes) IsGenerated(file string) bool {
+	return ga.attr(file, "linguist-generated") == "true"
+}
+
+// IsDocumentation reports whether file is marked linguist-documentation
+func (ga *GitAttributes) IsDocumentation(file string) bool {
+	return ga.attr(file, "linguist-documentation") == "true"
+}
+
+// attr evaluates the value of attribute name for file, walking .gitattributes files from the repository
+// root down to file's directory and taking the last matching pattern
+func (ga *GitAttributes) attr(file, name string) string {
+	value := ""
+	base := path.Base(file)
+	for _, dir := range parentDirs(path.Dir(file)) {
+		rel := relativeTo(dir, file)
+		for _, p := range ga.patternsFor(dir) {
+			if !gitattributeMatches(p.pattern, rel, base) {
+				continue
+			}
+			if v, ok := p.attrs[name]; ok {
+				value = v
+			}
+		}
+	}
+	return value
+}
+
+// gitattributeMatches reports whether pattern matches a file, given its path relative to the declaring
+// directory (rel) and its base name. A pattern with no slash matches at any depth below the declaring
+// directory, so it is matched against base rather than rel; a pattern with a slash anchors to rel itself
+func gitattributeMatches(pattern, rel, base string) bool {
+	if !strings.Contains(pattern, "/") {
+		matched, err := filepath.Match(pattern, base)
+		return err == nil && matched
+	}
+
+	matched, err := filepath.Match(strings.TrimPrefix(pattern, "/"), rel)
+	return err == nil && matched
+}
+
+// patternsFor returns the parsed .gitattributes patterns for dir, loading and caching them on first use
+func (ga *GitAttributes) patternsFor(dir string) []attributePattern {
+	if patterns, ok := ga.dirCache[dir]; ok {
+		return patterns
+	}
+
+	var patterns []attributePattern
+	content, exists, err := ga.backend.ReadFile(ga.repoPath, ga.revision, path.Join(dir, ".gitattributes"))
+	if err == nil && exists {
+		patterns = parseGitattributes(content)
+	}
+
+	ga.dirCache[dir] = patterns
+	return patterns
+}
+
+// parseGitattributes parses the contents of a .gitattributes file into its pattern lines
+func parseGitattributes(content string) []attributePattern {
+	var patterns []attributePattern
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		attrs := make(map[string]string)
+		for _, raw := range fields[1:] {
+			switch {
+			case strings.HasPrefix(raw, "-"):
+				attrs[raw[1:]] = "false"
+			case strings.HasPrefix(raw, "!"):
+				attrs[raw[1:]] = "unspecified"
+			case strings.Contains(raw, "="):
+				parts := strings.SplitN(raw, "=", 2)
+				attrs[parts[0]] = parts[1]
+			default:
+				attrs[raw] = "true"
+			}
+		}
+
+		patterns = append(patterns, attributePattern{pattern: fields[0], attrs: attrs})
+	}
+
+	return patterns
+}
+
+// parentDirs returns dir and every ancestor up to the repository root, ordered root-first
+func parentDirs(dir string) []string {
+	if dir == "." || dir == "" {
+		return []string{"."}
+	}
+
+	parts := strings.Split(dir, "/")
+	dirs := make([]string, 0, len(parts)+1)
+	dirs = append(dirs, ".")
+
+	cur := ""
+	for _, part := range parts {
+		if cur == "" {
+			cur = part
+		} else {
+			cur = cur + "/" + part
+		}
+		dirs = append(dirs, cur)
+	}
+
+	return dirs
+}
+
+// relativeTo returns file's path relative to dir, using forward slashes as git attribute patterns expect
+func relativeTo(dir, file string) string {
+	if dir == "." {
+		return file
+	}
+	return strings.TrimPrefix(file, dir+"/")
+}
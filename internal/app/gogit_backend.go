@@ -0,0 +1,415 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// GoGitBackend implements GitBackend in-process via github.com/go-git/go-git/v5, with no dependency on a
+// git binary being present on PATH
+type GoGitBackend struct{}
+
+// ListFiles walks the tree of the resolved revision and returns every blob path in it
+func (b *GoGitBackend) ListFiles(repoPath, revision string) ([]string, error) {
+	_, commit, err := b.openAndResolve(repoPath, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree for revision %s: %w", revision, err)
+	}
+
+	var files []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files = append(files, f.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk tree for revision %s: %w", revision, err)
+	}
+
+	return files, nil
+}
+
+// BlameFile runs go-git's line-level blame for file and groups consecutive lines from the same commit
+// into BlameChunks. Lines attributed to a commit in ignore are re-blamed against that commit's ancestors
+// until a non-ignored commit is found or history runs out
+func (b *GoGitBackend) BlameFile(repoPath, revision, file string, useCommitter bool, ignore IgnoreRevs) ([]BlameChunk, error) {
+	repo, commit, err := b.openAndResolve(repoPath, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	ignoreSet, err := b.resolveIgnoreSet(repo, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := git.Blame(commit, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame file %s: %w", file, err)
+	}
+
+	var chunks []BlameChunk
+	for lineNo, line := range result.Lines {
+		hash := line.Hash
+		if len(ignoreSet) > 0 {
+			if hash, err = b.lookThroughIgnored(repo, file, hash, lineNo, ignoreSet); err != nil {
+				return nil, err
+			}
+		}
+
+		author, err := b.resolveLineAuthor(repo, hash, useCommitter)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(chunks); n > 0 && chunks[n-1].CommitHash == hash.String() && chunks[n-1].Author == author {
+			chunks[n-1].Lines++
+			continue
+		}
+
+		chunks = append(chunks, BlameChunk{CommitHash: hash.String(), Author: author, Lines: 1})
+	}
+
+	return chunks, nil
+}
+
+// lookThroughIgnored re-blames file at lineNo against the parent of hash, repeating until it lands on a
+// commit outside ignoreSet or runs out of parents, mirroring `git blame --ignore-rev`. Lines introduced by
+// an ignored commit itself have no pre-image to look through to, and stay attributed to it
+func (b *GoGitBackend) lookThroughIgnored(repo *git.Repository, file string, hash plumbing.Hash, lineNo int, ignoreSet map[string]struct{}) (plumbing.Hash, error) {
+	for {
+		if _, ignored := ignoreSet[hash.String()]; !ignored {
+			return hash, nil
+		}
+
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return hash, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+
+		if commit.NumParents() == 0 {
+			return hash, nil
+		}
+
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return hash, fmt.Errorf("failed to read parent of commit %s: %w", hash, err)
+		}
+
+		parentLine, ok, err := b.mapLineToParent(commit, parent, file, lineNo)
+		if err != nil {
+			return hash, err
+		}
+		if !ok {
+			return hash, nil
+		}
+
+		result, err := git.Blame(parent, file)
+		if err != nil || parentLine >= len(result.Lines) {
+			return hash, nil
+		}
+
+		hash = result.Lines[parentLine].Hash
+		lineNo = parentLine
+	}
+}
+
+// mapLineToParent maps the 0-based lineNo in commit's version of file to its 0-based line number in
+// parent's version, by walking the hunks of the patch between them. ok is false when the line has no
+// pre-image in parent, i.e. it was added by commit
+func (b *GoGitBackend) mapLineToParent(commit, parent *object.Commit, file string, lineNo int) (int, bool, error) {
+	patch, err := parent.Patch(commit)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to diff commit %s: %w", commit.Hash, err)
+	}
+
+	for _, filePatch := range patch.FilePatches() {
+		from, to := filePatch.Files()
+		if to == nil || to.Path() != file {
+			continue
+		}
+		if from == nil {
+			// the whole file was added by commit, so no line in it has a pre-image
+			return 0, false, nil
+		}
+
+		childLine, parentLine := 0, 0
+		for _, chunk := range filePatch.Chunks() {
+			count := countChunkLines(chunk.Content())
+
+			switch chunk.Type() {
+			case diff.Equal:
+				if lineNo < childLine+count {
+					return parentLine + (lineNo - childLine), true, nil
+				}
+				childLine += count
+				parentLine += count
+			case diff.Delete:
+				parentLine += count
+			case diff.Add:
+				if lineNo < childLine+count {
+					return 0, false, nil
+				}
+				childLine += count
+			}
+		}
+
+		return 0, false, nil
+	}
+
+	// commit's patch doesn't touch file at all (e.g. it was only renamed) - line numbering is unchanged
+	return lineNo, true, nil
+}
+
+// countChunkLines counts the number of lines represented by a diff chunk's content
+func countChunkLines(content string) int {
+	if content == "" {
+		return 0
+	}
+
+	n := strings.Count(content, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		n++
+	}
+	return n
+}
+
+// resolveIgnoreSet resolves ignore's file and explicit revisions into a set of full commit hashes
+func (b *GoGitBackend) resolveIgnoreSet(repo *git.Repository, ignore IgnoreRevs) (map[string]struct{}, error) {
+	revs := append([]string{}, ignore.Revs...)
+
+	if ignore.File != "" {
+		data, err := os.ReadFile(ignore.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ignore-revs-file %s: %w", ignore.File, err)
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			revs = append(revs, line)
+		}
+	}
+
+	ignoreSet := make(map[string]struct{}, len(revs))
+	for _, rev := range revs {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve ignored revision %s: %w", rev, err)
+		}
+		ignoreSet[hash.String()] = struct{}{}
+	}
+
+	return ignoreSet, nil
+}
+
+// FileCreator walks the commit log restricted to file and returns the oldest commit that touches it
+func (b *GoGitBackend) FileCreator(repoPath, revision, file string, useCommitter bool) (string, string, error) {
+	repo, commit, err := b.openAndResolve(repoPath, revision)
+	if err != nil {
+		return "", "", err
+	}
+
+	commitIter, err := repo.Log(&git.LogOptions{From: commit.Hash, FileName: &file})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to log file %s: %w", file, err)
+	}
+	defer commitIter.Close()
+
+	var createCommit *object.Commit
+	err = commitIter.ForEach(func(c *object.Commit) error {
+		createCommit = c
+		return nil
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to walk log for file %s: %w", file, err)
+	}
+	if createCommit == nil {
+		return "", "", fmt.Errorf("no commits found for file %s", file)
+	}
+
+	return createCommit.Hash.String(), b.signatureName(createCommit, useCommitter), nil
+}
+
+// WalkDiffs walks the commits in rng via repo.Log and computes each commit's patch stats against its
+// first parent
+func (b *GoGitBackend) WalkDiffs(repoPath string, rng DiffRange) ([]CommitDiffStat, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %s: %w", repoPath, err)
+	}
+
+	toRev := rng.ToRev
+	if toRev == "" {
+		toRev = "HEAD"
+	}
+	toHash, err := repo.ResolveRevision(plumbing.Revision(toRev))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %w", toRev, err)
+	}
+
+	logOpts := &git.LogOptions{From: *toHash}
+	if rng.Since != "" {
+		since, err := time.Parse("2006-01-02", rng.Since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse --since %s: %w", rng.Since, err)
+		}
+		logOpts.Since = &since
+	}
+	if rng.Until != "" {
+		until, err := time.Parse("2006-01-02", rng.Until)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse --until %s: %w", rng.Until, err)
+		}
+		logOpts.Until = &until
+	}
+
+	var fromHash *plumbing.Hash
+	if rng.FromRev != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(rng.FromRev))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve revision %s: %w", rng.FromRev, err)
+		}
+		fromHash = hash
+	}
+
+	commitIter, err := repo.Log(logOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to log revision range: %w", err)
+	}
+	defer commitIter.Close()
+
+	var stats []CommitDiffStat
+	err = commitIter.ForEach(func(commit *object.Commit) error {
+		if fromHash != nil && commit.Hash == *fromHash {
+			return storer.ErrStop
+		}
+
+		stat, err := b.diffStatFor(commit)
+		if err != nil {
+			return err
+		}
+		stats = append(stats, stat)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk commit log: %w", err)
+	}
+
+	return stats, nil
+}
+
+// diffStatFor computes a commit's added/removed lines and touched files against its first parent
+func (b *GoGitBackend) diffStatFor(commit *object.Commit) (CommitDiffStat, error) {
+	stat := CommitDiffStat{
+		CommitHash: commit.Hash.String(),
+		Author:     fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email),
+	}
+
+	var parent *object.Commit
+	if commit.NumParents() > 0 {
+		p, err := commit.Parent(0)
+		if err != nil {
+			return stat, fmt.Errorf("failed to read parent of commit %s: %w", commit.Hash, err)
+		}
+		parent = p
+	}
+
+	patch, err := commit.Patch(parent)
+	if err != nil {
+		return stat, fmt.Errorf("failed to diff commit %s: %w", commit.Hash, err)
+	}
+
+	// commit.Patch diffs from commit to parent, so the commit's own additions are the patch's deletions
+	for _, fileStat := range patch.Stats() {
+		stat.LinesAdded += fileStat.Deletion
+		stat.LinesRemoved += fileStat.Addition
+		stat.Files = append(stat.Files, fileStat.Name)
+	}
+
+	return stat, nil
+}
+
+// ReadFile returns the contents of filePath as of revision, reading it straight from the resolved tree
+func (b *GoGitBackend) ReadFile(repoPath, revision, filePath string) (string, bool, error) {
+	_, commit, err := b.openAndResolve(repoPath, revision)
+	if err != nil {
+		return "", false, err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read tree for revision %s: %w", revision, err)
+	}
+
+	f, err := tree.File(filePath)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	content, err := f.Contents()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read contents of %s: %w", filePath, err)
+	}
+
+	return content, true, nil
+}
+
+// openAndResolve opens the repository at repoPath and resolves revision to its commit
+func (b *GoGitBackend) openAndResolve(repoPath, revision string) (*git.Repository, *object.Commit, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open repository %s: %w", repoPath, err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+
+	return repo, commit, nil
+}
+
+// resolveLineAuthor looks up the author (or committer) name for the commit that last touched a blamed line
+func (b *GoGitBackend) resolveLineAuthor(repo *git.Repository, hash plumbing.Hash, useCommitter bool) (string, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+
+	return b.signatureName(commit, useCommitter), nil
+}
+
+// signatureName renders a commit's author or committer signature in the "Name <email>" form used by
+// the exec backend, so both backends produce identical statsMap keys
+func (b *GoGitBackend) signatureName(commit *object.Commit, useCommitter bool) string {
+	sig := commit.Author
+	if useCommitter {
+		sig = commit.Committer
+	}
+
+	return fmt.Sprintf("%s <%s>", sig.Name, sig.Email)
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mailmapLineRe matches the supported .mailmap line forms:
+//
+//	Proper Name <proper-email>                              (2-field, matches by email)
+//	<proper-email> <commit-email>                           (matches by commit-email, keeps the name)
+//	Proper Name <proper-email> <commit-email>               (matches by commit-email)
+//	Proper Name <proper-email> Commit Name <commit-email>    (4-field, matches by email and commit-name)
+var mailmapLineRe = regexp.MustCompile(`^(?:([^<]+?)\s*)?<([^>]+)>(?:\s*(?:([^<]+?)\s*)?<([^>]+)>)?\s*$`)
+
+// mailmapRule is a single parsed .mailmap entry
+type mailmapRule struct {
+	properName  string
+	properEmail string
+	commitName  string
+	commitEmail string
+}
+
+// Mailmap canonicalizes "Name <email>" identities read from git blame through a .mailmap file, collapsing
+// entries such as "Jane Doe <jane@old.com>" and "jane <jane@new.com>" into a single identity
+type Mailmap struct {
+	rules []mailmapRule
+}
+
+// NewMailmap parses the contents of a .mailmap file. Unrecognized or blank lines are skipped
+func NewMailmap(content string) *Mailmap {
+	mm := &Mailmap{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := mailmapLineRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		mm.rules = append(mm.rules, mailmapRule{
+			properName:  match[1],
+			properEmail: match[2],
+			commitName:  match[3],
+			commitEmail: match[4],
+		})
+	}
+
+	return mm
+}
+
+// Canonicalize rewrites a "Name <email>" identity through the mailmap's rules, matching by commit email
+// (and, for the 4-field form, commit name). It returns identity unchanged if no rule matches
+func (mm *Mailmap) Canonicalize(identity string) string {
+	if mm == nil {
+		return identity
+	}
+
+	name, email := splitNameEmail(identity)
+
+	for _, rule := range mm.rules {
+		if rule.commitEmail != "" {
+			if !strings.EqualFold(rule.commitEmail, email) {
+				continue
+			}
+			if rule.commitName != "" && rule.commitName != name {
+				continue
+			}
+		} else if !strings.EqualFold(rule.properEmail, email) {
+			continue
+		}
+
+		properName := rule.properName
+		if properName == "" {
+			properName = name
+		}
+
+		return properName + " <" + rule.properEmail + ">"
+	}
+
+	return identity
+}
+
+// splitNameEmail splits a "Name <email>" identity into its name and email parts
+func splitNameEmail(identity string) (string, string) {
+	i := strings.Index(identity, "<")
+	j := strings.Index(identity, ">")
+	if i < 0 || j < 0 || j < i {
+		return identity, ""
+	}
+
+	return strings.TrimSpace(identity[:i]), identity[i+1 : j]
+}
@@ -0,0 +1,16 @@
+package app
+
+import "testing"
+
+// TestMailmapCanonicalizeExecIdentity guards against the "Name <email>" identity format drifting between
+// backends again: Canonicalize must match by email regardless of which backend produced the identity
+func TestMailmapCanonicalizeExecIdentity(t *testing.T) {
+	mm := NewMailmap("Dave Proper <dave@proper.com> <dave@d.com>\n")
+
+	identity := "Dave <dave@d.com>"
+	want := "Dave Proper <dave@proper.com>"
+
+	if got := mm.Canonicalize(identity); got != want {
+		t.Errorf("Canonicalize(%q) = %q, want %q", identity, got, want)
+	}
+}
@@ -6,62 +6,152 @@ import (
 	"fmt"
 	"gitfame/configs"
 	"golang.org/x/sync/errgroup"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"slices"
-	"strconv"
 	"strings"
 	"sync"
 	"text/tabwriter"
 )
 
-const (
-	CommitLen     = 40
-	CommitLineLen = 46
-)
-
 type Stats struct {
-	Name       string `json:"name"`
-	Lines      int    `json:"lines"`
-	Commits    int    `json:"commits"`
-	Files      int    `json:"files"`
-	commitsMap map[string]struct{}
+	Name         string `json:"name"`
+	Lines        int    `json:"lines"`
+	Commits      int    `json:"commits"`
+	Files        int    `json:"files"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	commitsMap   map[string]struct{}
 }
 
 type StatsCollector struct {
 	configs.Config
-	Stats    []Stats
-	statsMap map[string]*Stats
-	mu       sync.Mutex
+	Stats         []Stats
+	statsMap      map[string]*Stats
+	backend       GitBackend
+	gitAttributes *GitAttributes
+	mailmap       *Mailmap
+	eventsOut     io.Writer
+	eventsCloser  io.Closer
+	eventsErr     error
+	total         int
+	done          int
+	mu            sync.Mutex
 }
 
-func NewStatsCollector(config configs.Config) *StatsCollector {
+func NewStatsCollector(config configs.Config) (*StatsCollector, error) {
+	backend, err := NewGitBackend(config.Backend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up git backend: %w", err)
+	}
+
+	mailmap, err := loadMailmap(backend, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mailmap: %w", err)
+	}
+
+	eventsOut, eventsCloser, err := openEventsOut(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events output: %w", err)
+	}
+
 	return &StatsCollector{
-		Config:   config,
-		statsMap: make(map[string]*Stats),
+		Config:        config,
+		statsMap:      make(map[string]*Stats),
+		backend:       backend,
+		gitAttributes: NewGitAttributes(backend, config.RepoPath, config.Revision),
+		mailmap:       mailmap,
+		eventsOut:     eventsOut,
+		eventsCloser:  eventsCloser,
+	}, nil
+}
+
+// openEventsOut opens the sink for --format=ndjson-events: the --events-out file if set, stdout
+// otherwise, or nil when ndjson-events is not the active format
+func openEventsOut(config configs.Config) (io.Writer, io.Closer, error) {
+	if config.Format != "ndjson-events" {
+		return nil, nil, nil
+	}
+
+	if config.EventsOut == "" {
+		return os.Stdout, nil, nil
+	}
+
+	file, err := os.Create(config.EventsOut)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create events-out file %s: %w", config.EventsOut, err)
+	}
+
+	return file, file, nil
+}
+
+// Close releases any resources opened by StatsCollector, such as an --events-out file. It also reports
+// any error encountered while writing to the events sink during CollectStats, in preference to an error
+// from closing the file
+func (sc *StatsCollector) Close() error {
+	var closeErr error
+	if sc.eventsCloser != nil {
+		closeErr = sc.eventsCloser.Close()
+	}
+
+	if err := sc.eventsError(); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// loadMailmap reads the .mailmap contents for config, preferring an explicit --mailmap path over the
+// repository root's .mailmap at config.Revision
+func loadMailmap(backend GitBackend, config configs.Config) (*Mailmap, error) {
+	if config.MailmapPath != "" {
+		data, err := os.ReadFile(config.MailmapPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mailmap %s: %w", config.MailmapPath, err)
+		}
+		return NewMailmap(string(data)), nil
+	}
+
+	content, exists, err := backend.ReadFile(config.RepoPath, config.Revision, ".mailmap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .mailmap: %w", err)
 	}
+	if !exists {
+		return NewMailmap(""), nil
+	}
+
+	return NewMailmap(content), nil
 }
 
-// CollectStats collects git statistics, processes each repository file in a separate goroutine. Result saves in Stats list of StatsCollector
+// CollectStats collects git statistics and saves the result in the Stats list of StatsCollector. In
+// "blame" Mode (the default) it processes each repository file in a separate goroutine; in "diff" Mode
+// it walks the configured commit range instead, see collectDiffStats
 func (sc *StatsCollector) CollectStats() error {
-	cmd := exec.Command("git", "ls-tree", "-r", sc.Config.Revision, "--name-only")
-	cmd.Dir = sc.Config.RepoPath
+	if sc.Config.Mode == "diff" {
+		return sc.collectDiffStats()
+	}
 
-	output, err := cmd.Output()
+	files, err := sc.backend.ListFiles(sc.Config.RepoPath, sc.Config.Revision)
 	if err != nil {
 		return fmt.Errorf("failed to list files in repository: %w", err)
 	}
 
-	files := strings.Split(strings.TrimSpace(string(output)), "\n")
 	filteredFiles := sc.filterFiles(files)
+	sc.total = len(filteredFiles)
 
 	eg := errgroup.Group{}
 	for _, file := range filteredFiles {
+		file := file
 		eg.Go(func() error {
-			if err = sc.processFile(file); err != nil {
+			sc.Emit(Event{"type": "file_started", "path": file})
+
+			authors, err := sc.processFile(file)
+			if err != nil {
 				return fmt.Errorf("failed to process file %s: %w", file, err)
 			}
+
+			sc.Emit(Event{"type": "file_done", "path": file, "authors": authors})
+			sc.emitProgress()
 			return nil
 		})
 	}
@@ -75,11 +165,67 @@ func (sc *StatsCollector) CollectStats() error {
 		sc.Stats = append(sc.Stats, *stat)
 	}
 
-	return nil
+	sc.Emit(Event{"type": "summary", "stats": sc.Stats})
+
+	return sc.eventsError()
+}
+
+// collectDiffStats walks the commit range bounded by Since/Until/FromRev/ToRev and attributes each
+// commit's added/removed lines to its author, instead of blaming a single revision snapshot
+func (sc *StatsCollector) collectDiffStats() error {
+	rng := DiffRange{
+		FromRev: sc.Config.FromRev,
+		ToRev:   sc.Config.ToRev,
+		Since:   sc.Config.Since,
+		Until:   sc.Config.Until,
+	}
+	if rng.ToRev == "" {
+		rng.ToRev = sc.Config.Revision
+	}
+
+	commitDiffs, err := sc.backend.WalkDiffs(sc.Config.RepoPath, rng)
+	if err != nil {
+		return fmt.Errorf("failed to walk commit range: %w", err)
+	}
+
+	filesByAuthor := make(map[string]map[string]struct{})
+	for _, diff := range commitDiffs {
+		author := sc.mailmap.Canonicalize(diff.Author)
+
+		if _, exists := sc.statsMap[author]; !exists {
+			sc.statsMap[author] = &Stats{Name: author, commitsMap: make(map[string]struct{})}
+			filesByAuthor[author] = make(map[string]struct{})
+		}
+
+		sc.statsMap[author].commitsMap[diff.CommitHash] = struct{}{}
+		sc.statsMap[author].LinesAdded += diff.LinesAdded
+		sc.statsMap[author].LinesRemoved += diff.LinesRemoved
+
+		for _, file := range diff.Files {
+			if sc.isFileIncluded(file) {
+				filesByAuthor[author][file] = struct{}{}
+			}
+		}
+	}
+
+	for author, stat := range sc.statsMap {
+		stat.Commits = len(stat.commitsMap)
+		stat.Files = len(filesByAuthor[author])
+		sc.Stats = append(sc.Stats, *stat)
+	}
+
+	sc.Emit(Event{"type": "summary", "stats": sc.Stats})
+
+	return sc.eventsError()
 }
 
-// PrintStats prints collected in CollectStats git statistics in different formats, depending on Format from Config
+// PrintStats prints collected in CollectStats git statistics in different formats, depending on Format from Config.
+// In ndjson-events mode, stats were already streamed as events during CollectStats, so this is a no-op
 func (sc *StatsCollector) PrintStats() error {
+	if sc.Config.Format == "ndjson-events" {
+		return nil
+	}
+
 	sc.sortStats()
 
 	var err error
@@ -105,10 +251,7 @@ func (sc *StatsCollector) PrintStats() error {
 func (sc *StatsCollector) filterFiles(files []string) []string {
 	var filteredFiles []string
 	for _, file := range files {
-		if file != "" &&
-			sc.hasExtension(file) &&
-			!sc.matchesAnyPattern(file, sc.Config.Excludes) &&
-			(len(sc.Config.RestrictTo) == 0 || sc.matchesAnyPattern(file, sc.Config.RestrictTo)) {
+		if file != "" && sc.isFileIncluded(file) {
 			filteredFiles = append(filteredFiles, file)
 		}
 	}
@@ -116,6 +259,30 @@ func (sc *StatsCollector) filterFiles(files []string) []string {
 	return filteredFiles
 }
 
+// isFileIncluded is the single-file predicate behind filterFiles, also used when filtering the files
+// touched by commits in collectDiffStats
+func (sc *StatsCollector) isFileIncluded(file string) bool {
+	return sc.hasExtension(file) &&
+		!sc.matchesAnyPattern(file, sc.Config.Excludes) &&
+		(len(sc.Config.RestrictTo) == 0 || sc.matchesAnyPattern(file, sc.Config.RestrictTo)) &&
+		!sc.isLinguistExcluded(file)
+}
+
+// isLinguistExcluded reports whether file should be skipped based on its .gitattributes linguist-vendored,
+// linguist-generated or linguist-documentation markers, honoring the --include-* opt-back-in flags
+func (sc *StatsCollector) isLinguistExcluded(file string) bool {
+	if !sc.Config.IncludeVendored && sc.gitAttributes.IsVendored(file) {
+		return true
+	}
+	if !sc.Config.IncludeGenerated && sc.gitAttributes.IsGenerated(file) {
+		return true
+	}
+	if !sc.Config.IncludeDocumentation && sc.gitAttributes.IsDocumentation(file) {
+		return true
+	}
+	return false
+}
+
 // matchesAnyPattern is a helper function for filterFiles that finds matches between file and glob patterns
 func (sc *StatsCollector) matchesAnyPattern(file string, patterns []string) bool {
 	for _, pattern := range patterns {
@@ -145,68 +312,37 @@ func (sc *StatsCollector) hasExtension(file string) bool {
 	return false
 }
 
-// processFile collects git statistics for file via git blame command. Result saves in statsMap and commitsMap of Stats
-func (sc *StatsCollector) processFile(file string) error {
-	cmd := exec.Command("git", "blame", "--porcelain", sc.Config.Revision, "--", file)
-	cmd.Dir = sc.Config.RepoPath
-
-	output, err := cmd.Output()
+// processFile collects git statistics for file via the configured GitBackend. Result saves in statsMap and
+// commitsMap of Stats. It returns the authors attributed for file, for the ndjson-events "file_done" event
+func (sc *StatsCollector) processFile(file string) ([]string, error) {
+	ignore := IgnoreRevs{File: sc.Config.IgnoreRevsFile, Revs: sc.Config.IgnoreRevs}
+	chunks, err := sc.backend.BlameFile(sc.Config.RepoPath, sc.Config.Revision, file, sc.Config.UseCommitter, ignore)
 	if err != nil {
-		return fmt.Errorf("failed to run git blame: %w", err)
+		return nil, fmt.Errorf("failed to blame file %s: %w", file, err)
 	}
 
-	commits := make(map[string]string)
 	authors := make(map[string]struct{})
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-
-	for i, line := range lines {
-		if sc.isCommitLine(line) {
-			var author string
-			commitInfo := strings.Split(line, " ")
-			commitHash := commitInfo[0]
-
-			if auth, exist := commits[commitHash]; !exist {
-				author = sc.getAuthor(lines[i:])
-				commits[commitHash] = author
-
-				sc.mu.Lock()
-				if _, exists := sc.statsMap[author]; !exists {
-					sc.statsMap[author] = &Stats{Name: author, commitsMap: make(map[string]struct{})}
-				}
-				sc.statsMap[author].commitsMap[commitHash] = struct{}{}
-				sc.mu.Unlock()
-
-			} else {
-				author = auth
-			}
-
-			authors[author] = struct{}{}
+	for _, chunk := range chunks {
+		author := sc.mailmap.Canonicalize(chunk.Author)
 
-			var commitLinesCount int
-			if commitLinesCount, err = strconv.Atoi(commitInfo[3]); err != nil {
-				return fmt.Errorf("failed to parse commit line count %s: %w", line, err)
-			}
-
-			sc.mu.Lock()
-			sc.statsMap[author].Lines += commitLinesCount
-			sc.mu.Unlock()
+		sc.mu.Lock()
+		if _, exists := sc.statsMap[author]; !exists {
+			sc.statsMap[author] = &Stats{Name: author, commitsMap: make(map[string]struct{})}
 		}
+		sc.statsMap[author].commitsMap[chunk.CommitHash] = struct{}{}
+		sc.statsMap[author].Lines += chunk.Lines
+		sc.mu.Unlock()
+
+		authors[author] = struct{}{}
 	}
 
-	// if git blame gave no info (file is empty) but we want to define the creator of the file
+	// if blame gave no info (file is empty) but we want to define the creator of the file
 	if len(authors) == 0 {
-		logCmd := exec.Command("git", "log", sc.Config.Revision, "--", file)
-		logCmd.Dir = sc.Config.RepoPath
-
-		output, err = logCmd.Output()
+		createCommit, fileAuthor, err := sc.backend.FileCreator(sc.Config.RepoPath, sc.Config.Revision, file, sc.Config.UseCommitter)
 		if err != nil {
-			return fmt.Errorf("failed to run git log: %w", err)
+			return nil, fmt.Errorf("failed to find file creator for %s: %w", file, err)
 		}
-
-		lines = strings.Split(strings.TrimSpace(string(output)), "\n")
-		createCommit := strings.Split(lines[0], " ")[1]
-		fileAuthor := strings.TrimPrefix(lines[1], "Author: ")
-		fileAuthor = fileAuthor[:strings.Index(fileAuthor, "<")-1]
+		fileAuthor = sc.mailmap.Canonicalize(fileAuthor)
 
 		sc.mu.Lock()
 		if _, exists := sc.statsMap[fileAuthor]; !exists {
@@ -215,46 +351,21 @@ func (sc *StatsCollector) processFile(file string) error {
 		sc.statsMap[fileAuthor].commitsMap[createCommit] = struct{}{}
 		sc.statsMap[fileAuthor].Files++
 		sc.mu.Unlock()
+
+		return []string{fileAuthor}, nil
 	}
 
+	authorNames := make([]string, 0, len(authors))
 	for author := range authors {
 		sc.mu.Lock()
 		sc.statsMap[author].Files++
 		sc.mu.Unlock()
-	}
 
-	return nil
-}
-
-// isCommitLine is a helper function for processFile that determines whether the output line of git blame command
-// contains information about commit
-func (sc *StatsCollector) isCommitLine(line string) bool {
-	if len(line) < CommitLineLen || len(strings.Split(line, " ")) < 4 {
-		return false
+		authorNames = append(authorNames, author)
 	}
+	slices.Sort(authorNames)
 
-	for _, c := range line[:CommitLen] {
-		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
-			return false
-		}
-	}
-
-	return true
-}
-
-// getAuthor returns the author or commiter of the commit, depending on the UseCommiter config
-//
-// - lines: []string - a list of lines that starts with the target commit line
-func (sc *StatsCollector) getAuthor(lines []string) string {
-	if sc.Config.UseCommitter {
-		for _, line := range lines {
-			if strings.HasPrefix(line, "committer") {
-				return strings.TrimPrefix(line, "committer ")
-			}
-		}
-	}
-
-	return strings.TrimPrefix(lines[1], "author ")
+	return authorNames, nil
 }
 
 // sortStats sorts statistics depending on OrderBy param of Config. Operates on the Stats list
@@ -292,12 +403,12 @@ func (sc *StatsCollector) sortStats() {
 func (sc *StatsCollector) printTabular(stats []Stats) error {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
 
-	if _, err := fmt.Fprintln(w, "Name\tLines\tCommits\tFiles"); err != nil {
+	if _, err := fmt.Fprintln(w, "Name\tLines\tCommits\tFiles\tLines Added\tLines Removed"); err != nil {
 		return fmt.Errorf("failed to print header: %w", err)
 	}
 
 	for _, stat := range stats {
-		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", stat.Name, stat.Lines, stat.Commits, stat.Files); err != nil {
+		if _, err := fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\n", stat.Name, stat.Lines, stat.Commits, stat.Files, stat.LinesAdded, stat.LinesRemoved); err != nil {
 			return fmt.Errorf("failed to print stats line: %w", err)
 		}
 	}
@@ -314,12 +425,20 @@ func (sc *StatsCollector) printTabular(stats []Stats) error {
 func (sc *StatsCollector) printCSV(stats []Stats) error {
 	w := csv.NewWriter(os.Stdout)
 
-	if err := w.Write([]string{"Name", "Lines", "Commits", "Files"}); err != nil {
+	if err := w.Write([]string{"Name", "Lines", "Commits", "Files", "Lines Added", "Lines Removed"}); err != nil {
 		return fmt.Errorf("failed to print header: %w", err)
 	}
 
 	for _, stat := range stats {
-		if err := w.Write([]string{stat.Name, fmt.Sprintf("%d", stat.Lines), fmt.Sprintf("%d", stat.Commits), fmt.Sprintf("%d", stat.Files)}); err != nil {
+		row := []string{
+			stat.Name,
+			fmt.Sprintf("%d", stat.Lines),
+			fmt.Sprintf("%d", stat.Commits),
+			fmt.Sprintf("%d", stat.Files),
+			fmt.Sprintf("%d", stat.LinesAdded),
+			fmt.Sprintf("%d", stat.LinesRemoved),
+		}
+		if err := w.Write(row); err != nil {
 			return fmt.Errorf("failed to print stats: %w", err)
 		}
 	}